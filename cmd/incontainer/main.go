@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -27,6 +28,10 @@ type DetailedResult struct {
 	InContainer bool                      `json:"in_container"`
 	Type        incontainer.ContainerType `json:"type"`
 	Confidence  float64                   `json:"confidence"`
+	Rootless    bool                      `json:"rootless"`
+	Metadata    map[string]string         `json:"metadata,omitempty"`
+	Sandbox     incontainer.SandboxType   `json:"sandbox"`
+	BuildPhase  incontainer.BuildPhase    `json:"build_phase"`
 	Details     map[string]CheckResult    `json:"details"`
 }
 
@@ -62,10 +67,10 @@ func main() {
 		return
 	}
 
-	result := incontainer.Detect()
+	result, details := incontainer.DefaultRegistry.Detect(context.Background())
 
 	if *verbose || *jsonOutput {
-		detailed := getDetailedResult(result)
+		detailed := toDetailedResult(result, details)
 
 		if *jsonOutput {
 			encoder := json.NewEncoder(os.Stdout)
@@ -94,23 +99,13 @@ func main() {
 	}
 }
 
-func getDetailedResult(result incontainer.Result) DetailedResult {
-	details := make(map[string]CheckResult)
-
-	// Run individual checks to get detailed results
-	checks := map[string]func() (bool, incontainer.ContainerType, float64){
-		"docker_env": incontainer.CheckDockerEnv,
-		"cgroup":     incontainer.CheckCgroup,
-		"kubernetes": incontainer.CheckKubernetes,
-		"podman":     incontainer.CheckPodman,
-	}
-
-	for name, checkFunc := range checks {
-		found, containerType, confidence := checkFunc()
-		details[name] = CheckResult{
-			Found:      found,
-			Type:       containerType,
-			Confidence: confidence,
+func toDetailedResult(result incontainer.Result, details map[string]incontainer.DetectorResult) DetailedResult {
+	checkResults := make(map[string]CheckResult, len(details))
+	for name, d := range details {
+		checkResults[name] = CheckResult{
+			Found:      d.Found,
+			Type:       d.Type,
+			Confidence: d.Confidence,
 		}
 	}
 
@@ -118,7 +113,11 @@ func getDetailedResult(result incontainer.Result) DetailedResult {
 		InContainer: result.InContainer,
 		Type:        result.Type,
 		Confidence:  result.Confidence,
-		Details:     details,
+		Rootless:    result.Rootless,
+		Metadata:    result.Metadata,
+		Sandbox:     result.Sandbox,
+		BuildPhase:  result.BuildPhase,
+		Details:     checkResults,
 	}
 }
 
@@ -128,6 +127,15 @@ func printVerboseResult(result DetailedResult) {
 	fmt.Printf("In Container: %t\n", result.InContainer)
 	fmt.Printf("Detected Type: %s\n", result.Type)
 	fmt.Printf("Confidence: %.2f\n", result.Confidence)
+	fmt.Printf("Rootless: %t\n", result.Rootless)
+	fmt.Printf("Sandbox: %s\n", result.Sandbox)
+	fmt.Printf("Build Phase: %s\n", result.BuildPhase)
+	if len(result.Metadata) > 0 {
+		fmt.Printf("Metadata:\n")
+		for k, v := range result.Metadata {
+			fmt.Printf("  %s: %s\n", k, v)
+		}
+	}
 	fmt.Printf("\nDetailed Checks:\n")
 
 	for name, check := range result.Details {
@@ -16,9 +16,8 @@
 package incontainer
 
 import (
-	"bufio"
-	"os"
-	"strings"
+	"context"
+	"sync"
 )
 
 // ContainerType represents the type of container detected
@@ -33,140 +32,144 @@ const (
 	Podman ContainerType = "podman"
 	// LXC represents LXC container
 	LXC ContainerType = "lxc"
+	// WindowsContainer represents a Windows process-isolated container
+	WindowsContainer ContainerType = "windows"
+	// HyperVContainer represents a Windows Hyper-V isolated container
+	HyperVContainer ContainerType = "hyperv"
+	// Buildah represents a Buildah build-time container
+	Buildah ContainerType = "buildah"
+	// Nomad represents a HashiCorp Nomad task allocation
+	Nomad ContainerType = "nomad"
+	// ECSFargate represents an AWS ECS task running on Fargate
+	ECSFargate ContainerType = "ecs-fargate"
+	// CloudRun represents a Google Cloud Run revision
+	CloudRun ContainerType = "cloud-run"
+	// FlyIO represents a Fly.io machine
+	FlyIO ContainerType = "fly-io"
+	// GitHubActions represents a GitHub Actions runner
+	GitHubActions ContainerType = "github-actions"
+	// Generic represents a container detected through a signal that doesn't identify which
+	// runtime is in use, such as a PID-1 host-PID mismatch on a cgroup driver this package
+	// doesn't otherwise recognize (e.g. a raw unshare, systemd-nspawn, or unrecognized OCI
+	// runtime).
+	Generic ContainerType = "generic"
 	// Unknown represents unknown container type
 	Unknown ContainerType = "unknown"
 )
 
+// SandboxType represents a VM- or userspace-kernel-isolated runtime that a container may be
+// running under, orthogonal to the container engine reported in Result.Type — e.g. "Docker
+// container running on Kata" versus "Docker container on runc".
+type SandboxType string
+
+const (
+	// None means no sandboxed runtime was detected.
+	None SandboxType = "none"
+	// Kata represents the Kata Containers VM-isolated runtime.
+	Kata SandboxType = "kata"
+	// GVisor represents Google's gVisor userspace-kernel runtime.
+	GVisor SandboxType = "gvisor"
+	// Firecracker represents AWS Firecracker microVMs (e.g. Lambda, Fargate).
+	Firecracker SandboxType = "firecracker"
+	// NabulaEdge is reserved for NabulaEdge sandboxed runtime detection; no probe currently
+	// reports it.
+	NabulaEdge SandboxType = "nabulaedge"
+)
+
+// BuildPhase represents an image-build tool's environment (docker build, BuildKit, Buildah),
+// which looks like a container but has distinct semantics: no init, ephemeral, network may be
+// off. Tools like linters and package installers can check this to skip network calls.
+type BuildPhase string
+
+const (
+	// BuildPhaseNone means no image-build environment was detected.
+	BuildPhaseNone BuildPhase = "none"
+	// DockerBuild represents a classic `docker build` (non-BuildKit) step. No probe currently
+	// reports it: its only in-container signal, PID 1's /bin/sh -c cmdline, is indistinguishable
+	// from an ordinary running container whose image uses a shell-form CMD/ENTRYPOINT.
+	DockerBuild BuildPhase = "docker-build"
+	// BuildKit represents a `docker buildx` / BuildKit build step.
+	BuildKit BuildPhase = "buildkit"
+	// BuildahBuild represents a `buildah bud` / `podman build` step.
+	BuildahBuild BuildPhase = "buildah-build"
+)
+
 // Result contains the detection result
 type Result struct {
 	InContainer bool
 	Type        ContainerType
 	Confidence  float64 // 0.0 to 1.0
+	Rootless    bool
+	// Metadata holds engine-reported key/value pairs, such as the engine, name, id,
+	// and image fields Podman writes to /run/.containerenv.
+	Metadata   map[string]string
+	Sandbox    SandboxType
+	BuildPhase BuildPhase
 }
 
-// Detect checks if the current process is running inside a container
+// Detect checks if the current process is running inside a container. It is a thin wrapper
+// around DefaultRegistry.Detect using a background context; callers that want to bound probing
+// or inspect per-detector results should call DefaultRegistry.Detect directly.
 func Detect() Result {
-	result := Result{
-		InContainer: false,
-		Type:        Unknown,
-		Confidence:  0.0,
-	}
-
-	// Check multiple indicators
-	indicators := []func() (bool, ContainerType, float64){
-		CheckDockerEnv,
-		CheckCgroup,
-		CheckKubernetes,
-		CheckPodman,
-		// Colima, Rancher Desktop, and OrbStack treated as Docker via CheckDockerEnv
-	}
-
-	maxConfidence := 0.0
-	detectedType := Unknown
-
-	for _, check := range indicators {
-		if found, containerType, confidence := check(); found {
-			result.InContainer = true
-			if confidence > maxConfidence {
-				maxConfidence = confidence
-				detectedType = containerType
-			}
-		}
-	}
-
-	result.Type = detectedType
-	result.Confidence = maxConfidence
-
+	result, _ := DefaultRegistry.Detect(context.Background())
 	return result
 }
 
-// CheckDockerEnv checks for Docker-specific indicators
-func CheckDockerEnv() (bool, ContainerType, float64) {
-	// Check for .dockerenv file
-	if _, err := os.Stat("/.dockerenv"); err == nil {
-		return true, Docker, 0.9
-	}
+// hexByte is a 256-entry lookup table marking which bytes are hexadecimal digits, so
+// isHexString can test each byte with a single branch-free index instead of four comparisons.
+var hexByte [256]bool
 
-	// Check for docker in hostname
-	if hostname, err := os.Hostname(); err == nil {
-		if len(hostname) == 12 && isHexString(hostname) {
-			return true, Docker, 0.7
-		}
+func init() {
+	for _, c := range "0123456789abcdefABCDEF" {
+		hexByte[c] = true
 	}
-
-	return false, Unknown, 0.0
 }
 
-// CheckCgroup checks /proc/1/cgroup for container indicators
-func CheckCgroup() (bool, ContainerType, float64) {
-	file, err := os.Open("/proc/1/cgroup")
-	if err != nil {
-		return false, Unknown, 0.0
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if strings.Contains(line, "docker") {
-			return true, Docker, 0.8
-		}
-		if strings.Contains(line, "kubepods") {
-			return true, Kubernetes, 0.8
-		}
-		if strings.Contains(line, "lxc") {
-			return true, LXC, 0.8
-		}
-		if strings.Contains(line, "podman") {
-			return true, Podman, 0.8
+// isHexString checks if a string contains only hexadecimal characters
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !hexByte[s[i]] {
+			return false
 		}
 	}
-
-	return false, Unknown, 0.0
+	return true
 }
 
-// CheckKubernetes checks for Kubernetes-specific indicators
-func CheckKubernetes() (bool, ContainerType, float64) {
-	// Check for Kubernetes service account
-	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount"); err == nil {
-		return true, Kubernetes, 0.9
-	}
-
-	// Check for Kubernetes environment variables
-	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
-		return true, Kubernetes, 0.8
-	}
-
-	return false, Unknown, 0.0
-}
+var (
+	cacheMu      sync.Mutex
+	cacheValid   bool
+	cachedResult Result
+)
 
-// CheckPodman checks for Podman-specific indicators
-func CheckPodman() (bool, ContainerType, float64) {
-	// Check for Podman environment variable
-	if os.Getenv("container") == "podman" {
-		return true, Podman, 0.9
+// DetectCached returns a memoized detection Result, probing only once across the lifetime of
+// the process (or since the last Invalidate). Libraries that gate logging or metrics on
+// container-ness and call this on every hot-loop iteration should use DetectCached instead of
+// Detect. Call Invalidate after changes that could affect the result, such as unshare(2);
+// DetectCached and Invalidate are both safe to call concurrently.
+func DetectCached() Result {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if !cacheValid {
+		cachedResult = Detect()
+		cacheValid = true
 	}
-
-	return false, Unknown, 0.0
+	return cachedResult
 }
 
-// isHexString checks if a string contains only hexadecimal characters
-func isHexString(s string) bool {
-	for _, c := range s {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
-	}
-	return true
+// Invalidate clears the cached Result used by DetectCached, forcing the next call to re-probe.
+// Intended for tests that simulate a changed container environment.
+func Invalidate() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheValid = false
 }
 
 // IsInContainer is a convenience function that returns true if running in any container
 func IsInContainer() bool {
-	return Detect().InContainer
+	return DetectCached().InContainer
 }
 
 // GetContainerType returns the detected container type
 func GetContainerType() ContainerType {
-	return Detect().Type
+	return DetectCached().Type
 }
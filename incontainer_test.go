@@ -15,6 +15,7 @@
 package incontainer
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -76,6 +77,46 @@ func TestIsHexString(t *testing.T) {
 	}
 }
 
+func TestDetectCached(t *testing.T) {
+	defer Invalidate()
+
+	first := DetectCached()
+	second := DetectCached()
+
+	if first.InContainer != second.InContainer || first.Type != second.Type || first.Confidence != second.Confidence {
+		t.Error("DetectCached() should return the same Result until Invalidate is called")
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	defer Invalidate()
+
+	DetectCached()
+	Invalidate()
+
+	if cacheValid {
+		t.Error("Invalidate should reset the cache so the next DetectCached call re-probes")
+	}
+}
+
+func TestDetectCachedConcurrentInvalidate(t *testing.T) {
+	defer Invalidate()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			DetectCached()
+		}()
+		go func() {
+			defer wg.Done()
+			Invalidate()
+		}()
+	}
+	wg.Wait()
+}
+
 // Benchmark tests
 func BenchmarkDetect(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -83,6 +124,12 @@ func BenchmarkDetect(b *testing.B) {
 	}
 }
 
+func BenchmarkDetectCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		DetectCached()
+	}
+}
+
 func BenchmarkIsInContainer(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		IsInContainer()
@@ -0,0 +1,397 @@
+// Copyright 2025 Yoshiki Shibukawa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package incontainer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+)
+
+// platformDetectors returns the Detectors used on non-Windows platforms.
+func platformDetectors() []Detector {
+	return []Detector{
+		funcDetector{"docker_env", CheckDockerEnv},
+		funcDetector{"cgroup", CheckCgroup},
+		funcDetector{"cgroup_v2", CheckCgroupV2},
+		funcDetector{"kubernetes", CheckKubernetes},
+		funcDetector{"podman", CheckPodman},
+		// Colima, Rancher Desktop, and OrbStack treated as Docker via CheckDockerEnv
+		rootlessDetector{},
+	}
+}
+
+// CheckDockerEnv checks for Docker-specific indicators
+func CheckDockerEnv() (bool, ContainerType, float64) {
+	// Check for .dockerenv file
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true, Docker, 0.9
+	}
+
+	// Check for docker in hostname
+	if hostname, err := os.Hostname(); err == nil {
+		if len(hostname) == 12 && isHexString(hostname) {
+			return true, Docker, 0.7
+		}
+	}
+
+	return false, Unknown, 0.0
+}
+
+// CheckCgroup checks /proc/1/cgroup for container indicators
+func CheckCgroup() (bool, ContainerType, float64) {
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false, Unknown, 0.0
+	}
+
+	for len(data) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+
+		if bytes.Contains(line, []byte("docker")) {
+			return true, Docker, 0.8
+		}
+		if bytes.Contains(line, []byte("kubepods")) {
+			return true, Kubernetes, 0.8
+		}
+		if bytes.Contains(line, []byte("lxc")) {
+			return true, LXC, 0.8
+		}
+		if bytes.Contains(line, []byte("podman")) {
+			return true, Podman, 0.8
+		}
+	}
+
+	return false, Unknown, 0.0
+}
+
+// CheckCgroupV2 checks for container indicators on cgroup v2 / unified-hierarchy systems
+// (Fedora 31+, recent Ubuntu, rootless Podman), where /proc/1/cgroup no longer carries a
+// driver name for CheckCgroup to match against.
+func CheckCgroupV2() (bool, ContainerType, float64) {
+	if !isCgroupV2() {
+		return false, Unknown, 0.0
+	}
+
+	if found, containerType, confidence := checkMountinfoHints(); found {
+		return found, containerType, confidence
+	}
+
+	return checkPid1HostMismatch()
+}
+
+// isCgroupV2 reports whether the host uses the cgroup v2 unified hierarchy.
+func isCgroupV2() bool {
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err == nil {
+		return true
+	}
+
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(string(data), "0::")
+}
+
+// checkMountinfoHints looks for container-path hints on the cgroup2 mount in
+// /proc/self/mountinfo, since the driver name is no longer available in /proc/1/cgroup.
+func checkMountinfoHints() (bool, ContainerType, float64) {
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return false, Unknown, 0.0
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.Contains(line, "cgroup2") {
+			continue
+		}
+
+		switch {
+		case strings.Contains(line, "docker-"):
+			return true, Docker, 0.7
+		case strings.Contains(line, "libpod-"), strings.Contains(line, "podman-"):
+			return true, Podman, 0.7
+		case strings.Contains(line, "kubepods.slice"):
+			return true, Kubernetes, 0.7
+		}
+	}
+
+	return false, Unknown, 0.0
+}
+
+// checkPid1HostMismatch inspects /proc/1/sched, whose first line's parenthesized group holds
+// PID 1's host-visible PID. Inside a container that value differs from 1 regardless of which
+// cgroup driver is used. This alone can't identify the runtime, so it reports Generic rather
+// than Unknown — Unknown is reserved for "not found" so Detect's InContainer/Type stay
+// consistent.
+func checkPid1HostMismatch() (bool, ContainerType, float64) {
+	data, err := os.ReadFile("/proc/1/sched")
+	if err != nil {
+		return false, Unknown, 0.0
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	hostPID, ok := parseSchedHostPID(line)
+	if !ok || hostPID == "1" {
+		return false, Unknown, 0.0
+	}
+
+	return true, Generic, 0.4
+}
+
+// parseSchedHostPID extracts the PID from a /proc/<pid>/sched header line of the form
+// "<comm> (<pid>, #threads: <n>)", e.g. "process_api (1, #threads: 16)". The trailing
+// "#threads: <n>)" field is not the PID, so it must be pulled out of the parenthesized group
+// instead of taken as the last whitespace-separated field.
+func parseSchedHostPID(line string) (string, bool) {
+	open := strings.IndexByte(line, '(')
+	if open < 0 {
+		return "", false
+	}
+
+	rest := line[open+1:]
+	comma := strings.IndexByte(rest, ',')
+	if comma < 0 {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest[:comma]), true
+}
+
+// CheckKubernetes checks for Kubernetes-specific indicators
+func CheckKubernetes() (bool, ContainerType, float64) {
+	// Check for Kubernetes service account
+	if _, err := os.Stat("/var/run/secrets/kubernetes.io/serviceaccount"); err == nil {
+		return true, Kubernetes, 0.9
+	}
+
+	// Check for Kubernetes environment variables
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true, Kubernetes, 0.8
+	}
+
+	return false, Unknown, 0.0
+}
+
+// CheckPodman checks for Podman-specific indicators
+func CheckPodman() (bool, ContainerType, float64) {
+	// Check for Podman environment variable
+	if os.Getenv("container") == "podman" {
+		return true, Podman, 0.9
+	}
+
+	return false, Unknown, 0.0
+}
+
+// CheckRootless checks for rootless container execution, which CheckPodman's env-var check
+// misses whenever `container=` isn't exported (e.g. some rootless Buildah invocations). It also
+// parses /run/.containerenv, which Podman writes with engine/name/id/image key/value pairs, into
+// metadata the caller can inspect. If ctx is already done, no files are read; ctx is checked
+// again before the uid_map/gid_map reads, but a read already under way is not interrupted.
+func CheckRootless(ctx context.Context) (bool, ContainerType, float64, map[string]string) {
+	if ctx.Err() != nil {
+		return false, Unknown, 0.0, nil
+	}
+
+	meta := readContainerEnv()
+	rootless := isRootlessUserNamespace(ctx)
+
+	if !rootless && len(meta) == 0 {
+		return false, Unknown, 0.0, nil
+	}
+
+	containerType := Podman
+	if strings.HasPrefix(meta["engine"], "buildah") {
+		containerType = Buildah
+	}
+
+	confidence := 0.6
+	switch {
+	case len(meta) > 0:
+		confidence = 0.9
+	case rootless:
+		confidence = 0.8
+	}
+
+	return true, containerType, confidence, meta
+}
+
+// isRootlessUserNamespace reports whether the process is running in a user namespace whose root
+// maps to a non-root host UID/GID — the canonical rootless Podman / Buildah signature. If ctx is
+// already done, no files are read.
+func isRootlessUserNamespace(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return hasRootlessMapping("/proc/self/uid_map") && hasRootlessMapping("/proc/self/gid_map")
+}
+
+// hasRootlessMapping reports whether a uid_map/gid_map file contains the single-line
+// "0 <N> <size>" mapping with N != 0 that a rootless user namespace uses for its root ID.
+func hasRootlessMapping(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		return false
+	}
+
+	fields := strings.Fields(lines[0])
+	if len(fields) != 3 {
+		return false
+	}
+
+	return fields[0] == "0" && fields[1] != "0"
+}
+
+// readContainerEnv parses the key/value pairs Podman writes to /run/.containerenv.
+func readContainerEnv() map[string]string {
+	data, err := os.ReadFile("/run/.containerenv")
+	if err != nil {
+		return nil
+	}
+
+	meta := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found {
+			continue
+		}
+		meta[key] = strings.Trim(value, `"`)
+	}
+
+	return meta
+}
+
+// CheckSandbox checks for VM- or userspace-kernel-isolated runtimes (Kata Containers, gVisor,
+// Firecracker) that a container-vs-host signal alone cannot distinguish from plain runc. If ctx
+// is already done, no files are read; ctx is not checked again between the probes.
+func CheckSandbox(ctx context.Context) SandboxType {
+	if ctx.Err() != nil {
+		return None
+	}
+	if isGVisor() {
+		return GVisor
+	}
+	if isFirecracker() {
+		return Firecracker
+	}
+	if isKata() {
+		return Kata
+	}
+	return None
+}
+
+// isGVisor checks for gVisor's "(gVisor ...)" build marker in /proc/version.
+func isGVisor() bool {
+	data, err := os.ReadFile("/proc/version")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "gVisor")
+}
+
+// isKata checks for the hypervisor CPU flag plus a KVM/QEMU DMI product name — true of any
+// container on a KVM-virtualized host, Kata or not — corroborated by the kata-containers runtime
+// marker it actually leaves behind. /.dockerenv isn't used here: it's set by every Docker
+// container regardless of sandbox, so it can't tell Kata apart from plain runc.
+func isKata() bool {
+	cpuinfo, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil || !strings.Contains(string(cpuinfo), "hypervisor") {
+		return false
+	}
+
+	product, err := os.ReadFile("/sys/class/dmi/id/product_name")
+	if err != nil {
+		return false
+	}
+	if !strings.Contains(string(product), "KVM") && !strings.Contains(string(product), "QEMU") {
+		return false
+	}
+
+	_, err = os.Stat("/run/kata-containers")
+	return err == nil
+}
+
+// isFirecracker checks for the Amazon EC2 BIOS vendor combined with the small vCPU count
+// typical of a Firecracker microVM.
+func isFirecracker() bool {
+	vendor, err := os.ReadFile("/sys/class/dmi/id/bios_vendor")
+	if err != nil || !strings.Contains(string(vendor), "Amazon EC2") {
+		return false
+	}
+
+	cpuinfo, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return false
+	}
+
+	return strings.Count(string(cpuinfo), "processor\t:") <= 2
+}
+
+// CheckBuildEnvironment checks for image-build tool environments (BuildKit, Buildah) that look
+// like a container but have distinct semantics: no init, ephemeral, network may be off. A classic
+// `docker build` step is not detected, so DockerBuild is never returned. If ctx is already done,
+// no files are read.
+func CheckBuildEnvironment(ctx context.Context) BuildPhase {
+	if ctx.Err() != nil {
+		return BuildPhaseNone
+	}
+	if isBuildKit() {
+		return BuildKit
+	}
+	if isBuildahBuild() {
+		return BuildahBuild
+	}
+	return BuildPhaseNone
+}
+
+// isBuildKit checks for BuildKit's env vars, its /run/buildkit mount, and the buildkit-* hostname
+// convention it uses alongside the usual /.dockerenv marker.
+func isBuildKit() bool {
+	if os.Getenv("BUILDKIT_HOST") != "" || os.Getenv("BUILDKIT_FRONTEND") != "" {
+		return true
+	}
+
+	if _, err := os.Stat("/run/buildkit"); err == nil {
+		return true
+	}
+
+	if hostname, err := os.Hostname(); err == nil && strings.HasPrefix(hostname, "buildkit-") {
+		if _, err := os.Stat("/.dockerenv"); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBuildahBuild checks /run/.containerenv for Buildah's own engine= marker, which it writes
+// during `buildah bud` / `podman build` the same way it does for rootless runs.
+func isBuildahBuild() bool {
+	return strings.HasPrefix(readContainerEnv()["engine"], "buildah")
+}
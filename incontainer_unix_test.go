@@ -0,0 +1,96 @@
+// Copyright 2025 Yoshiki Shibukawa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package incontainer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckCgroupV2(t *testing.T) {
+	found, containerType, confidence := CheckCgroupV2()
+
+	if confidence < 0.0 || confidence > 1.0 {
+		t.Errorf("Confidence should be between 0.0 and 1.0, got %f", confidence)
+	}
+
+	if !found && containerType != Unknown {
+		t.Error("If not found, Type should be Unknown")
+	}
+
+	if found && containerType == Unknown {
+		t.Error("If found, Type should not be Unknown")
+	}
+}
+
+func TestParseSchedHostPID(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+		ok       bool
+	}{
+		{"process_api (1, #threads: 16)", "1", true},
+		{"bash (4821, #threads: 1)", "4821", true},
+		{"init (1, #threads: 1)", "1", true},
+		{"garbage line with no parens", "", false},
+	}
+
+	for _, test := range tests {
+		pid, ok := parseSchedHostPID(test.line)
+		if ok != test.ok || pid != test.expected {
+			t.Errorf("parseSchedHostPID(%q) = (%q, %v), expected (%q, %v)", test.line, pid, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestCheckRootless(t *testing.T) {
+	found, _, confidence, meta := CheckRootless(context.Background())
+
+	if confidence < 0.0 || confidence > 1.0 {
+		t.Errorf("Confidence should be between 0.0 and 1.0, got %f", confidence)
+	}
+
+	if !found && meta != nil {
+		t.Error("If not found, Metadata should be nil")
+	}
+}
+
+func TestCheckRootlessCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	found, containerType, confidence, meta := CheckRootless(ctx)
+	if found || containerType != Unknown || confidence != 0.0 || meta != nil {
+		t.Error("CheckRootless should report not-found on a canceled context")
+	}
+}
+
+func TestCheckSandbox(t *testing.T) {
+	switch CheckSandbox(context.Background()) {
+	case None, Kata, GVisor, Firecracker, NabulaEdge:
+	default:
+		t.Error("CheckSandbox() returned an unrecognized SandboxType")
+	}
+}
+
+func TestCheckBuildEnvironment(t *testing.T) {
+	switch CheckBuildEnvironment(context.Background()) {
+	case BuildPhaseNone, DockerBuild, BuildKit, BuildahBuild:
+	default:
+		t.Error("CheckBuildEnvironment() returned an unrecognized BuildPhase")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Yoshiki Shibukawa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package incontainer
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// platformDetectors returns the Detectors used on Windows.
+func platformDetectors() []Detector {
+	return []Detector{
+		funcDetector{"windows_container", CheckWindowsContainer},
+	}
+}
+
+// CheckWindowsContainer checks for Windows process-isolated and Hyper-V isolated container
+// indicators. The host compute service marks containerized guests by writing a ContainerType
+// value (1 = process-isolated, 2 = Hyper-V) under the guest's registry, so that is checked first.
+func CheckWindowsContainer() (bool, ContainerType, float64) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Virtual Machine\Guest\Parameters`, registry.QUERY_VALUE)
+	if err == nil {
+		defer key.Close()
+		if containerType, _, err := key.GetIntegerValue("ContainerType"); err == nil {
+			switch containerType {
+			case 1:
+				return true, WindowsContainer, 0.9
+			case 2:
+				return true, HyperVContainer, 0.9
+			}
+		}
+	}
+
+	// The docker_engine named pipe is only reachable from inside a Windows container.
+	if _, err := os.Stat(`\\.\pipe\docker_engine`); err == nil {
+		return true, WindowsContainer, 0.7
+	}
+
+	// Docker assigns Windows containers the same 12-character hex hostname convention as Linux.
+	if hostname, err := os.Hostname(); err == nil {
+		if len(hostname) == 12 && isHexString(hostname) {
+			return true, WindowsContainer, 0.6
+		}
+	}
+
+	return false, Unknown, 0.0
+}
+
+// isRootlessUserNamespace is a no-op on Windows; rootless user namespaces are a Linux concept.
+func isRootlessUserNamespace(ctx context.Context) bool {
+	return false
+}
+
+// CheckRootless is a no-op on Windows; rootless user namespaces are a Linux concept.
+func CheckRootless(ctx context.Context) (bool, ContainerType, float64, map[string]string) {
+	return false, Unknown, 0.0, nil
+}
+
+// CheckSandbox is a no-op on Windows; the probes for Kata, gVisor, and Firecracker all rely on
+// Linux-specific /proc and /sys files.
+func CheckSandbox(ctx context.Context) SandboxType {
+	return None
+}
+
+// CheckBuildEnvironment is a no-op on Windows; the BuildKit and Buildah probes look for Linux
+// container markers such as /run/buildkit, /.dockerenv, and /run/.containerenv.
+func CheckBuildEnvironment(ctx context.Context) BuildPhase {
+	return BuildPhaseNone
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Yoshiki Shibukawa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incontainer
+
+import (
+	"context"
+	"os"
+)
+
+// Detector is a single container-environment probe that can be registered with a Registry.
+// ctx lets callers bound any filesystem or network access the probe performs.
+type Detector interface {
+	// Name identifies the detector, used as the key in Registry.Detect's per-detector results.
+	Name() string
+	Check(ctx context.Context) (found bool, containerType ContainerType, confidence float64, meta map[string]string, err error)
+}
+
+// DetectorResult is a single detector's outcome, as returned by Registry.Detect.
+type DetectorResult struct {
+	Found      bool
+	Type       ContainerType
+	Confidence float64
+	Metadata   map[string]string
+	Err        error
+}
+
+// Registry runs a set of Detectors and aggregates their results into a Result.
+type Registry struct {
+	detectors []Detector
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a Detector to the registry. Detectors run in registration order, and the one
+// reporting the highest confidence wins Result.Type.
+func (r *Registry) Register(d Detector) {
+	r.detectors = append(r.detectors, d)
+}
+
+// Detect runs every registered Detector and returns the aggregated Result along with
+// per-detector results keyed by Detector.Name().
+func (r *Registry) Detect(ctx context.Context) (Result, map[string]DetectorResult) {
+	result := Result{Type: Unknown, Sandbox: None, BuildPhase: BuildPhaseNone}
+	details := make(map[string]DetectorResult, len(r.detectors))
+
+	maxConfidence := 0.0
+	detectedType := Unknown
+
+	for _, d := range r.detectors {
+		found, containerType, confidence, meta, err := d.Check(ctx)
+		details[d.Name()] = DetectorResult{
+			Found:      found,
+			Type:       containerType,
+			Confidence: confidence,
+			Metadata:   meta,
+			Err:        err,
+		}
+
+		if err != nil || !found {
+			continue
+		}
+
+		result.InContainer = true
+		if len(meta) > 0 {
+			if result.Metadata == nil {
+				result.Metadata = make(map[string]string, len(meta))
+			}
+			for k, v := range meta {
+				result.Metadata[k] = v
+			}
+		}
+		if confidence > maxConfidence {
+			maxConfidence = confidence
+			detectedType = containerType
+		}
+	}
+
+	result.Type = detectedType
+	result.Confidence = maxConfidence
+	result.Rootless = isRootlessUserNamespace(ctx)
+	result.Sandbox = CheckSandbox(ctx)
+	result.BuildPhase = CheckBuildEnvironment(ctx)
+
+	return result, details
+}
+
+// funcDetector adapts the package's func() (bool, ContainerType, float64) check functions to
+// the Detector interface.
+type funcDetector struct {
+	name  string
+	check func() (bool, ContainerType, float64)
+}
+
+func (d funcDetector) Name() string { return d.name }
+
+func (d funcDetector) Check(ctx context.Context) (bool, ContainerType, float64, map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return false, Unknown, 0.0, nil, err
+	}
+	found, containerType, confidence := d.check()
+	return found, containerType, confidence, nil, nil
+}
+
+// rootlessDetector adapts CheckRootless, which also reports metadata parsed from
+// /run/.containerenv, to the Detector interface.
+type rootlessDetector struct{}
+
+func (rootlessDetector) Name() string { return "rootless" }
+
+func (rootlessDetector) Check(ctx context.Context) (bool, ContainerType, float64, map[string]string, error) {
+	found, containerType, confidence, meta := CheckRootless(ctx)
+	return found, containerType, confidence, meta, nil
+}
+
+// envVarDetector reports a container environment whenever a single marker environment variable
+// is set, as used by Nomad, ECS/Fargate, Cloud Run, Fly.io, and GitHub Actions runners.
+type envVarDetector struct {
+	name          string
+	envKey        string
+	containerType ContainerType
+	confidence    float64
+}
+
+func (d envVarDetector) Name() string { return d.name }
+
+func (d envVarDetector) Check(ctx context.Context) (bool, ContainerType, float64, map[string]string, error) {
+	value := os.Getenv(d.envKey)
+	if value == "" {
+		return false, Unknown, 0.0, nil, nil
+	}
+	return true, d.containerType, d.confidence, map[string]string{d.envKey: value}, nil
+}
+
+// DefaultRegistry is the Registry used by the package-level Detect, IsInContainer, and
+// GetContainerType functions. It is pre-populated with the built-in Docker, cgroup, Kubernetes,
+// Podman, and platform-specific checks, plus common PaaS/CI environment markers.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	for _, d := range platformDetectors() {
+		r.Register(d)
+	}
+
+	r.Register(envVarDetector{"nomad", "NOMAD_ALLOC_ID", Nomad, 0.8})
+	r.Register(envVarDetector{"ecs_fargate", "ECS_CONTAINER_METADATA_URI_V4", ECSFargate, 0.9})
+	r.Register(envVarDetector{"cloud_run", "K_SERVICE", CloudRun, 0.8})
+	r.Register(envVarDetector{"fly_io", "FLY_ALLOC_ID", FlyIO, 0.8})
+	r.Register(envVarDetector{"github_actions", "GITHUB_ACTIONS", GitHubActions, 0.7})
+
+	return r
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Yoshiki Shibukawa
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incontainer
+
+import (
+	"context"
+	"testing"
+)
+
+type stubDetector struct {
+	name       string
+	found      bool
+	t          ContainerType
+	confidence float64
+	meta       map[string]string
+	err        error
+}
+
+func (d stubDetector) Name() string { return d.name }
+
+func (d stubDetector) Check(ctx context.Context) (bool, ContainerType, float64, map[string]string, error) {
+	return d.found, d.t, d.confidence, d.meta, d.err
+}
+
+func TestRegistryDetect(t *testing.T) {
+	r := NewRegistry()
+	r.Register(stubDetector{name: "low", found: true, t: Podman, confidence: 0.3})
+	r.Register(stubDetector{name: "high", found: true, t: Docker, confidence: 0.9, meta: map[string]string{"engine": "docker"}})
+	r.Register(stubDetector{name: "miss", found: false})
+
+	result, details := r.Detect(context.Background())
+
+	if !result.InContainer {
+		t.Error("InContainer should be true when any detector finds a match")
+	}
+	if result.Type != Docker {
+		t.Errorf("Type should be Docker (highest confidence), got %s", result.Type)
+	}
+	if result.Confidence != 0.9 {
+		t.Errorf("Confidence should be 0.9, got %f", result.Confidence)
+	}
+	if result.Metadata["engine"] != "docker" {
+		t.Errorf("Metadata should carry the winning detector's metadata, got %v", result.Metadata)
+	}
+	if len(details) != 3 {
+		t.Errorf("expected 3 per-detector results, got %d", len(details))
+	}
+	if details["miss"].Found {
+		t.Error("miss detector should be reported as not found")
+	}
+}
+
+func TestDefaultRegistryDetect(t *testing.T) {
+	result, details := DefaultRegistry.Detect(context.Background())
+
+	if result.Confidence < 0.0 || result.Confidence > 1.0 {
+		t.Errorf("Confidence should be between 0.0 and 1.0, got %f", result.Confidence)
+	}
+	if len(details) == 0 {
+		t.Error("DefaultRegistry should have registered detectors")
+	}
+}